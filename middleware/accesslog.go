@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// statusRecorder - Wraps http.ResponseWriter to capture the status code written downstream
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// AccessLog - Middleware that logs a structured entry per request: method, path, upstream
+// host, status, and duration. Path and upstream are read from the request after the wrapped
+// handler returns, so for a proxied request path is the one proxyRequest rewrote. Upstream is the
+// backend host only when proxyRequest set it on this same request; for requests that are not
+// proxied, or when a handler in between passed on a copy of the request, it is the client's Host
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: res, status: http.StatusOK}
+		next.ServeHTTP(rec, req)
+		log.WithFields(log.Fields{
+			"method":   req.Method,
+			"path":     req.URL.Path,
+			"upstream": req.Host,
+			"status":   rec.status,
+			"duration": time.Since(start).String(),
+		}).Info("request")
+	})
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import "net/http"
+
+// BasicAuth - Middleware that gates a handler behind HTTP Basic Auth, e.g. to keep arbitrary
+// clients from registering services through the discovery endpoint
+func BasicAuth(username string, password string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			u, p, ok := req.BasicAuth()
+			if !ok || u != username || p != password {
+				res.Header().Set("WWW-Authenticate", `Basic realm="novis"`)
+				http.Error(res, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(res, req)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	log "github.com/sirupsen/logrus"
+)
+
+// RateLimitOptions - Token bucket options for RateLimit
+type RateLimitOptions struct {
+	RequestsPerSecond int
+	Burst             int
+	// Storage - Optional Redis client (e.g. (*novis.Novis).Storage()) to share the limit
+	// across instances; falls back to an in-process bucket per client IP when nil
+	Storage *redis.Client
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// RateLimit - Build a middleware that rate limits requests per client IP. Without Storage it uses
+// an in-process token bucket per IP; with Storage it counts requests per IP in a fixed one-second
+// window in Redis instead, and Burst is not used
+func RateLimit(opts RateLimitOptions) func(http.Handler) http.Handler {
+	rps := opts.RequestsPerSecond
+	if rps <= 0 {
+		rps = 10
+	}
+	burst := opts.Burst
+	if burst <= 0 {
+		burst = rps
+	}
+	var mu sync.Mutex
+	buckets := map[string]*tokenBucket{}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			ip := clientIP(req)
+			var allowed bool
+			if opts.Storage != nil {
+				allowed = allowRedis(req.Context(), opts.Storage, ip, rps)
+			} else {
+				allowed = allowLocal(&mu, buckets, ip, rps, burst)
+			}
+			if !allowed {
+				http.Error(res, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(res, req)
+		})
+	}
+}
+
+// clientIP - Extract the client IP from the request's remote address
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// allowLocal - Refill and draw from an in-process token bucket for ip
+func allowLocal(mu *sync.Mutex, buckets map[string]*tokenBucket, ip string, rps int, burst int) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	now := time.Now()
+	b, ok := buckets[ip]
+	if !ok {
+		b = &tokenBucket{tokens: float64(burst), lastRefill: now}
+		buckets[ip] = b
+	}
+	b.tokens += now.Sub(b.lastRefill).Seconds() * float64(rps)
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.lastRefill = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// allowRedis - Count requests for ip in the current one-second window via Redis INCR/EXPIRE,
+// so the limit is shared across instances
+func allowRedis(ctx context.Context, client *redis.Client, ip string, rps int) bool {
+	key := "_NOVIS_RL_" + ip
+	count, err := client.Incr(ctx, key).Result()
+	if err != nil {
+		log.Warn(err.Error())
+		return true
+	}
+	if count == 1 {
+		if err := client.Expire(ctx, key, time.Second).Err(); err != nil {
+			log.Warn(err.Error())
+		}
+	}
+	return count <= int64(rps)
+}
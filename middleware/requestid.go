@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.nike.com/kxu16/novis"
+)
+
+// RequestID - Middleware that injects a request ID into the request context (under
+// novis.RequestIDCtxKey) and the X-Request-ID response header, mirroring Caddy's per-request
+// context replacer pattern
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get("X-Request-ID")
+		if len(id) == 0 {
+			id = generateRequestID()
+		}
+		res.Header().Set("X-Request-ID", id)
+		ctx := context.WithValue(req.Context(), novis.RequestIDCtxKey, id)
+		next.ServeHTTP(res, req.WithContext(ctx))
+	})
+}
+
+// generateRequestID - Generate a random hex request ID
+func generateRequestID() string {
+	b := make([]byte, 8)
+	_, _ = crand.Read(b)
+	return hex.EncodeToString(b)
+}
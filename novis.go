@@ -2,6 +2,8 @@ package novis
 
 import (
 	"context"
+	crand "crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +12,8 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -34,26 +38,208 @@ const (
 )
 
 const (
-	svcKey = "_NOVIS_SVCS_"
+	svcKey    = "_NOVIS_SVCS_"
+	eventsKey = "_NOVIS_EVENTS_"
 )
 
 // Service - Service Server
 type Service struct {
-	Host           string        `json:"host" yaml:"host"`
-	Path           string        `json:"path" yaml:"path"`
-	Status         ServiceStatus `json:"status"`
-	HealthCheckURL string        `json:"healthCheckURL" yaml:"healthCheckURL"`
-	reverseProxy   *httputil.ReverseProxy
-	mux            *sync.RWMutex
+	Host                          string        `json:"host" yaml:"host"`
+	Path                          string        `json:"path" yaml:"path"`
+	Status                        ServiceStatus `json:"status"`
+	HealthCheckURL                string        `json:"healthCheckURL" yaml:"healthCheckURL"`
+	HealthCheckMethod             string        `json:"healthCheckMethod,omitempty" yaml:"healthCheckMethod"`
+	HealthCheckExpectStatus       int           `json:"healthCheckExpectStatus,omitempty" yaml:"healthCheckExpectStatus"`
+	HealthCheckExpectBody         string        `json:"healthCheckExpectBody,omitempty" yaml:"healthCheckExpectBody"`
+	HealthCheckInterval           time.Duration `json:"healthCheckInterval,omitempty" yaml:"healthCheckInterval"`
+	HealthCheckTimeout            time.Duration `json:"healthCheckTimeout,omitempty" yaml:"healthCheckTimeout"`
+	HealthCheckUnhealthyThreshold int           `json:"healthCheckUnhealthyThreshold,omitempty" yaml:"healthCheckUnhealthyThreshold"`
+	HealthyThreshold              int           `json:"healthyThreshold,omitempty" yaml:"healthyThreshold"`
+	Weight                        int           `json:"weight" yaml:"weight"`
+	// BreakerState - Current circuit breaker state (CLOSED, OPEN, HALF_OPEN), for inspection
+	// via GetAllServices
+	BreakerState string `json:"breakerState,omitempty"`
+	failCount    int
+	okCount      int
+	breaker      *circuitBreaker
+	reverseProxy *httputil.ReverseProxy
+	mux          *sync.RWMutex
+}
+
+// breakerState - Circuit breaker state
+type breakerState string
+
+const (
+	breakerClosed   breakerState = "CLOSED"
+	breakerOpen     breakerState = "OPEN"
+	breakerHalfOpen breakerState = "HALF_OPEN"
+)
+
+// circuitBreaker - Per-service rolling-window circuit breaker (CLOSED -> OPEN -> HALF_OPEN)
+// that short-circuits proxyRequest once the recent failure ratio crosses a threshold
+type circuitBreaker struct {
+	mux           sync.Mutex
+	state         breakerState
+	window        []bool
+	windowSize    int
+	failureRatio  float64
+	baseCooldown  time.Duration
+	cooldown      time.Duration
+	maxCooldown   time.Duration
+	openedAt      time.Time
+	halfOpenInUse bool
+}
+
+// newBreaker - Build a circuit breaker from ProxyOptions, falling back to sane defaults
+func (n *Novis) newBreaker() *circuitBreaker {
+	window := n.opts.BreakerWindow
+	if window <= 0 {
+		window = 10
+	}
+	ratio := n.opts.BreakerFailureRatio
+	if ratio <= 0 {
+		ratio = 0.5
+	}
+	cooldown := n.opts.BreakerCooldown
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	maxCooldown := n.opts.BreakerMaxCooldown
+	if maxCooldown <= 0 {
+		maxCooldown = 5 * time.Minute
+	}
+	return &circuitBreaker{
+		state:        breakerClosed,
+		windowSize:   window,
+		failureRatio: ratio,
+		baseCooldown: cooldown,
+		cooldown:     cooldown,
+		maxCooldown:  maxCooldown,
+	}
+}
+
+// allow - Check whether a request may be sent upstream, opening a single HALF_OPEN probe once
+// the cooldown has elapsed; changed reports whether state flipped as a result
+func (b *circuitBreaker) allow() (ok bool, retryAfter time.Duration, changed bool) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	switch b.state {
+	case breakerHalfOpen:
+		if b.halfOpenInUse {
+			return false, time.Second, false
+		}
+		b.halfOpenInUse = true
+		return true, 0, false
+	case breakerOpen:
+		elapsed := time.Since(b.openedAt)
+		if elapsed < b.cooldown {
+			return false, b.cooldown - elapsed, false
+		}
+		b.state = breakerHalfOpen
+		b.halfOpenInUse = true
+		return true, 0, true
+	default: // breakerClosed
+		return true, 0, false
+	}
+}
+
+// record - Feed a request outcome into the breaker, tripping or resetting state as thresholds
+// are crossed; returns whether state changed
+func (b *circuitBreaker) record(failed bool) (changed bool) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	switch b.state {
+	case breakerOpen:
+		return false
+	case breakerHalfOpen:
+		if failed {
+			b.trip()
+		} else {
+			b.reset()
+		}
+		return true
+	default: // breakerClosed
+		b.window = append(b.window, failed)
+		if len(b.window) > b.windowSize {
+			b.window = b.window[len(b.window)-b.windowSize:]
+		}
+		if len(b.window) >= b.windowSize {
+			failures := 0
+			for _, f := range b.window {
+				if f {
+					failures++
+				}
+			}
+			if float64(failures)/float64(len(b.window)) >= b.failureRatio {
+				b.trip()
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// trip - Open the breaker, doubling the cooldown on a repeat trip (capped at maxCooldown)
+func (b *circuitBreaker) trip() {
+	if b.state == breakerHalfOpen {
+		b.cooldown *= 2
+		if b.cooldown > b.maxCooldown {
+			b.cooldown = b.maxCooldown
+		}
+	}
+	b.state = breakerOpen
+	b.openedAt = time.Now()
+	b.halfOpenInUse = false
+	b.window = nil
+}
+
+// reset - Close the breaker and restore the base cooldown
+func (b *circuitBreaker) reset() {
+	b.state = breakerClosed
+	b.cooldown = b.baseCooldown
+	b.halfOpenInUse = false
+	b.window = nil
+}
+
+// currentState - Get the breaker's current state
+func (b *circuitBreaker) currentState() breakerState {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.state
 }
 
 // Novis - Service Proxy
 type Novis struct {
-	services map[string]*Service
-	storage  *redis.Client
-	server   *http.Server
-	opts     *ProxyOptions
-	mux      sync.RWMutex
+	services            map[string][]*Service
+	storage             *redis.Client
+	events              *redis.PubSub
+	nodeID              string
+	server              *http.Server
+	opts                *ProxyOptions
+	middleware          []Middleware
+	discoveryMiddleware []Middleware
+	ctx                 context.Context
+	cancel              context.CancelFunc
+	mux                 sync.RWMutex
+	eventsMux           sync.Mutex
+}
+
+// Middleware - Wraps an http.Handler to add cross-cutting behavior such as auth, rate
+// limiting, logging, or tracing
+type Middleware func(http.Handler) http.Handler
+
+// ctxKey - Context key type to avoid collisions with values set by other packages
+type ctxKey string
+
+// RequestIDCtxKey - Context key under which middleware.RequestID stores the request ID
+const RequestIDCtxKey ctxKey = "novis.requestID"
+
+// serviceEvent - Registry change published to eventsKey so other Novis instances can apply it
+type serviceEvent struct {
+	Op      string   `json:"op"`
+	Path    string   `json:"path"`
+	Service *Service `json:"service,omitempty"`
+	Origin  string   `json:"origin"`
 }
 
 // ProxyOptions - Service Proxy options
@@ -61,6 +247,19 @@ type ProxyOptions struct {
 	Timeout      time.Duration
 	DiscoveryURL string
 	StorageOpts  *redis.Options
+	// HashKey - Selector used to derive the rendezvous hashing key for a request:
+	// "clientIP", "header:<Name>", or "path" (default)
+	HashKey string
+	// HealthCheckInterval - How often to probe services; defaults to 30s
+	HealthCheckInterval time.Duration
+	// BreakerWindow - Number of recent requests the circuit breaker considers; defaults to 10
+	BreakerWindow int
+	// BreakerFailureRatio - Failure ratio within BreakerWindow that trips the breaker; defaults to 0.5
+	BreakerFailureRatio float64
+	// BreakerCooldown - How long the breaker stays OPEN before a HALF_OPEN probe; defaults to 30s
+	BreakerCooldown time.Duration
+	// BreakerMaxCooldown - Cap on the cooldown after repeated trips; defaults to 5m
+	BreakerMaxCooldown time.Duration
 }
 
 // Configuration - Configuration
@@ -75,6 +274,14 @@ type Configuration struct {
 	} `yaml:"server"`
 }
 
+// generateNodeID - Generate a random identifier for this Novis instance, used to ignore
+// pub/sub events the instance published itself
+func generateNodeID() string {
+	b := make([]byte, 8)
+	_, _ = crand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 // New - Create a new proxy
 func New(port uint16, opts *ProxyOptions) *Novis {
 	if opts == nil {
@@ -82,11 +289,11 @@ func New(port uint16, opts *ProxyOptions) *Novis {
 			Timeout:      10 * time.Second,
 			DiscoveryURL: "discovery"}
 	}
-	n := &Novis{services: map[string]*Service{}, server: &http.Server{
-		Addr: fmt.Sprintf(":%d", port)}, opts: opts, storage: redis.NewClient(opts.StorageOpts)}
-	n.server.Handler = http.HandlerFunc(n.proxyRequest)
+	n := &Novis{services: map[string][]*Service{}, server: &http.Server{
+		Addr: fmt.Sprintf(":%d", port)}, opts: opts, storage: redis.NewClient(opts.StorageOpts), nodeID: generateNodeID()}
+	n.ctx, n.cancel = context.WithCancel(context.Background())
 	n.LoadFromStorage()
-	n.UpdateStorage()
+	go n.subscribeEvents(n.ctx)
 	return n
 }
 
@@ -101,96 +308,393 @@ func NewFromConfig(fileName string, storageOpts *redis.Options) (nn *Novis, err
 	if err != nil {
 		return nn, err
 	}
-	initialServices := map[string]*Service{}
+	initialServices := map[string][]*Service{}
 	for si := 0; si < len(yc.Server.Services); si++ {
-		sURL, err := url.Parse(yc.Server.Services[si].GetHost())
-		if err != nil {
+		if _, err := url.Parse(yc.Server.Services[si].GetHost()); err != nil {
 			return nn, err
 		}
-		proxy := httputil.NewSingleHostReverseProxy(sURL)
-		p := strings.Trim(yc.Server.Services[si].Path, "/")
-		initialServices[p] = &Service{
+		p := strings.ToLower(strings.Trim(yc.Server.Services[si].Path, "/"))
+		initialServices[p] = append(initialServices[p], &Service{
 			Host:           yc.Server.Services[si].GetHost(),
 			Path:           yc.Server.Services[si].GetPath(),
 			HealthCheckURL: yc.Server.Services[si].GetHealthCheckURL(),
-			reverseProxy:   proxy,
-			Status:         CHECKING}
+			Weight:         yc.Server.Services[si].GetWeight(),
+			Status:         CHECKING})
 	}
 	nn = &Novis{
-		services: initialServices,
+		services: map[string][]*Service{},
 		server: &http.Server{
 			Addr: fmt.Sprintf(":%d", yc.Port)},
 		opts: &ProxyOptions{
 			Timeout:      yc.Server.Timeout * time.Second,
 			DiscoveryURL: yc.Server.Discovery.Path,
 			StorageOpts:  storageOpts},
-		storage: redis.NewClient(storageOpts)}
-	nn.server.Handler = http.HandlerFunc(nn.proxyRequest)
+		storage: redis.NewClient(storageOpts),
+		nodeID:  generateNodeID()}
+	nn.ctx, nn.cancel = context.WithCancel(context.Background())
 	err = nn.LoadFromStorage()
-	nn.UpdateStorage()
+	for p, backends := range initialServices {
+		for _, s := range backends {
+			sURL, uErr := url.Parse(s.GetHost())
+			if uErr != nil {
+				log.Warn(uErr.Error())
+				continue
+			}
+			s.mux = &sync.RWMutex{}
+			s.reverseProxy = nn.newReverseProxy(p, sURL, s)
+			nn.addServiceLocal(s)
+			if sErr := nn.UpdateStorage(p, s); sErr != nil {
+				log.Warn(sErr.Error())
+			}
+			nn.publishEvent("add", p, s)
+		}
+	}
+	go nn.subscribeEvents(nn.ctx)
 	return nn, err
 }
 
-// LoadFromStorage - Load services from Storage
+// LoadFromStorage - Load services from the Redis service hash
 func (n *Novis) LoadFromStorage() (err error) {
 	ctx := context.Background()
-	res, err := n.storage.Get(ctx, svcKey).Result()
-	if err != nil {
-		if err == redis.Nil {
-			return nil
-		}
-		return err
-	}
-	var services map[string]Service
-	err = json.Unmarshal([]byte(res), &services)
+	res, err := n.storage.HGetAll(ctx, svcKey).Result()
 	if err != nil {
 		return err
 	}
-	for _, v := range services {
+	for _, raw := range res {
+		var v Service
+		if err = json.Unmarshal([]byte(raw), &v); err != nil {
+			return err
+		}
 		sURL, err := url.Parse(v.GetHost())
 		if err != nil {
 			return err
 		}
-		proxy := httputil.NewSingleHostReverseProxy(sURL)
-		n.AddService(&Service{
+		path := strings.ToLower(strings.Trim(v.Path, "/"))
+		svc := &Service{
 			Path:           v.GetPath(),
 			Host:           v.GetHost(),
 			HealthCheckURL: v.GetHealthCheckURL(),
-			reverseProxy:   proxy,
+			Weight:         v.GetWeight(),
+			Status:         v.Status,
 			mux:            &sync.RWMutex{},
-		})
+		}
+		svc.reverseProxy = n.newReverseProxy(path, sURL, svc)
+		n.addServiceLocal(svc)
+	}
+	return nil
+}
+
+// reconcile - Rebuild local state from a full snapshot of storage, used after a pub/sub disconnect
+func (n *Novis) reconcile() error {
+	n.mux.Lock()
+	n.services = map[string][]*Service{}
+	n.mux.Unlock()
+	return n.LoadFromStorage()
+}
+
+// addServiceLocal - Insert a backend into the in-memory map only, without touching storage or
+// pub/sub. A backend already registered for path+host (e.g. a re-announce through /discovery, or
+// a config/storage entry loaded twice) replaces the existing entry instead of appending a
+// duplicate, matching the replace-on-reregister semantics of the original single-backend map.
+func (n *Novis) addServiceLocal(service *Service) {
+	p := strings.ToLower(strings.Trim(service.Path, "/"))
+	if len(p) == 0 {
+		return
+	}
+	if service.breaker == nil {
+		service.breaker = n.newBreaker()
+		service.BreakerState = string(breakerClosed)
+	}
+	n.mux.Lock()
+	backends := n.services[p]
+	replaced := false
+	for i, b := range backends {
+		if hashField(p, b) == hashField(p, service) {
+			backends[i] = service
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		backends = append(backends, service)
+	}
+	n.services[p] = backends
+	n.mux.Unlock()
+}
+
+// newReverseProxy - Build a reverse proxy for a backend that feeds response and transport
+// outcomes into its circuit breaker via ModifyResponse and ErrorHandler
+func (n *Novis) newReverseProxy(path string, sURL *url.URL, service *Service) *httputil.ReverseProxy {
+	proxy := httputil.NewSingleHostReverseProxy(sURL)
+	proxy.ModifyResponse = func(res *http.Response) error {
+		n.recordBreakerOutcome(path, service, res.StatusCode >= http.StatusInternalServerError)
+		return nil
+	}
+	proxy.ErrorHandler = func(res http.ResponseWriter, req *http.Request, err error) {
+		n.recordBreakerOutcome(path, service, true)
+		log.WithField("URL", service.GetHost()).Warn(err.Error())
+		Respond(res, http.StatusBadGateway, nil, nil)
+	}
+	return proxy
+}
+
+// breakerAllow - Check whether a request to service may proceed, persisting and publishing
+// any resulting breaker state transition
+func (n *Novis) breakerAllow(path string, service *Service) (ok bool, retryAfter time.Duration) {
+	if service.breaker == nil {
+		return true, 0
+	}
+	ok, retryAfter, changed := service.breaker.allow()
+	if changed {
+		n.persistBreakerState(path, service)
+	}
+	return ok, retryAfter
+}
+
+// recordBreakerOutcome - Feed a proxied request outcome into service's breaker, persisting and
+// publishing any resulting state transition
+func (n *Novis) recordBreakerOutcome(path string, service *Service, failed bool) {
+	if service.breaker == nil {
+		return
+	}
+	if service.breaker.record(failed) {
+		n.persistBreakerState(path, service)
 	}
+}
+
+// persistBreakerState - Mirror the breaker's state onto Service.BreakerState and sync it
+func (n *Novis) persistBreakerState(path string, service *Service) {
+	service.setBreakerState(service.breaker.currentState())
+	if err := n.UpdateStorage(path, service); err != nil {
+		log.Warn(err.Error())
+	}
+	n.publishEvent("breaker", path, service)
+}
+
+// UpdateStorage - Persist a single backend into the Redis service hash (HSET), so concurrent
+// writers never clobber each other the way a whole-map SET would
+func (n *Novis) UpdateStorage(path string, service *Service) (err error) {
+	ctx := context.Background()
+	b, err := json.Marshal(service)
+	if err != nil {
+		return err
+	}
+	return n.storage.HSet(ctx, svcKey, hashField(path, service), string(b)).Err()
+}
+
+// removeServiceFromStorage - Remove a single backend from the Redis service hash
+func (n *Novis) removeServiceFromStorage(path string, service *Service) (err error) {
+	ctx := context.Background()
+	return n.storage.HDel(ctx, svcKey, hashField(path, service)).Err()
+}
+
+// publishEvent - Publish a registry change to eventsKey for other Novis instances to apply
+func (n *Novis) publishEvent(op string, path string, service *Service) {
+	ctx := context.Background()
+	ev := serviceEvent{Op: op, Path: path, Service: service, Origin: n.nodeID}
+	b, err := json.Marshal(ev)
+	if err != nil {
+		log.Warn(err.Error())
+		return
+	}
+	if err := n.storage.Publish(ctx, eventsKey, string(b)).Err(); err != nil {
+		log.Warn(err.Error())
+	}
+}
+
+// subscribeEvents - Apply registry changes published by other Novis instances, reconciling a
+// full snapshot from storage whenever the subscription disconnects, until ctx is cancelled.
+// Reconnect attempts back off exponentially (capped) so a down Redis doesn't get busy-spun.
+func (n *Novis) subscribeEvents(ctx context.Context) {
+	const baseBackoff = time.Second
+	const maxBackoff = 30 * time.Second
+	backoff := baseBackoff
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		n.eventsMux.Lock()
+		n.events = n.storage.Subscribe(ctx, eventsKey)
+		ch := n.events.Channel()
+		n.eventsMux.Unlock()
+	consume:
+		for {
+			select {
+			case <-ctx.Done():
+				n.closeEvents()
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					break consume
+				}
+				var ev serviceEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+					log.Warn(err.Error())
+					continue
+				}
+				if ev.Origin == n.nodeID || ev.Service == nil {
+					continue
+				}
+				n.applyRemoteEvent(ev)
+			}
+		}
+		err := n.reconcile()
+		if err != nil {
+			log.Warn(err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if err == nil {
+			backoff = baseBackoff
+		} else if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// closeEvents - Close the pub/sub subscription exactly once, guarding n.events against the
+// concurrent read/write between subscribeEvents and Shutdown
+func (n *Novis) closeEvents() (err error) {
+	n.eventsMux.Lock()
+	defer n.eventsMux.Unlock()
+	if n.events == nil {
+		return nil
+	}
+	err = n.events.Close()
+	n.events = nil
 	return err
 }
 
+// applyRemoteEvent - Apply a remote registry change to the local map without re-publishing it
+func (n *Novis) applyRemoteEvent(ev serviceEvent) {
+	switch ev.Op {
+	case "add":
+		sURL, err := url.Parse(ev.Service.GetHost())
+		if err != nil {
+			log.Warn(err.Error())
+			return
+		}
+		ev.Service.mux = &sync.RWMutex{}
+		ev.Service.reverseProxy = n.newReverseProxy(ev.Path, sURL, ev.Service)
+		n.addServiceLocal(ev.Service)
+	case "remove":
+		n.mux.Lock()
+		backends := n.services[ev.Path]
+		remaining := make([]*Service, 0, len(backends))
+		for _, b := range backends {
+			if b.GetHost() != ev.Service.Host {
+				remaining = append(remaining, b)
+			}
+		}
+		if len(remaining) > 0 {
+			n.services[ev.Path] = remaining
+		} else {
+			delete(n.services, ev.Path)
+		}
+		n.mux.Unlock()
+	case "pause", "resume", "status":
+		n.mux.RLock()
+		backends := n.services[ev.Path]
+		n.mux.RUnlock()
+		for _, b := range backends {
+			if b.GetHost() == ev.Service.Host {
+				b.SetStatus(ev.Service.Status)
+			}
+		}
+	case "breaker":
+		n.mux.RLock()
+		backends := n.services[ev.Path]
+		n.mux.RUnlock()
+		for _, b := range backends {
+			if b.GetHost() == ev.Service.Host {
+				b.setBreakerState(breakerState(ev.Service.BreakerState))
+			}
+		}
+	}
+}
+
+// Use - Register middleware to wrap the whole proxy handler, applied in registration order
+func (n *Novis) Use(mw ...Middleware) {
+	n.middleware = append(n.middleware, mw...)
+}
+
+// UseDiscovery - Register middleware to wrap only the discovery endpoint, e.g. a BasicAuth gate
+func (n *Novis) UseDiscovery(mw ...Middleware) {
+	n.discoveryMiddleware = append(n.discoveryMiddleware, mw...)
+}
+
+// Storage - Get the underlying Redis client, for middleware that needs shared state
+func (n *Novis) Storage() *redis.Client {
+	return n.storage
+}
+
+// buildHandler - Build the request handler: a mux with the discovery endpoint wrapped in its
+// own middleware, the whole thing wrapped in the middleware registered via Use
+func (n *Novis) buildHandler() http.Handler {
+	mux := http.NewServeMux()
+	var dh http.Handler = http.HandlerFunc(n.discoveryHandler)
+	for i := len(n.discoveryMiddleware) - 1; i >= 0; i-- {
+		dh = n.discoveryMiddleware[i](dh)
+	}
+	mux.Handle("/"+strings.ToLower(n.opts.DiscoveryURL), dh)
+	mux.Handle("/", http.HandlerFunc(n.proxyRequest))
+	var h http.Handler = mux
+	for i := len(n.middleware) - 1; i >= 0; i-- {
+		h = n.middleware[i](h)
+	}
+	return h
+}
+
 // Start - Start Server Proxy
 func (n *Novis) Start(cb func(*Novis)) error {
-	go n.healthCheck()
+	n.server.Handler = n.buildHandler()
+	go n.healthCheck(n.ctx)
 	if cb != nil {
 		go cb(n)
 	}
 	err := n.server.ListenAndServe()
-	return err
+	if err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
 }
 
-// healthCheck - Health check for services
-func (n *Novis) healthCheck() {
-	t := time.NewTicker(time.Second * 30)
+// healthCheck - Health check for services, until ctx is cancelled
+func (n *Novis) healthCheck(ctx context.Context) {
+	interval := n.opts.HealthCheckInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	t := time.NewTicker(interval)
 	defer t.Stop()
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case <-t.C:
-			for _, s := range n.services {
-				currentStatus := s.GetStatus()
-				if currentStatus != PAUSE {
-					sHost := s.GetHealthCheckURL()
-					status := isServiceAlive(sHost)
-					if currentStatus != status {
-						s.SetStatus(status)
-						err := n.UpdateStorage()
-						if err != nil {
+			n.mux.RLock()
+			snapshot := make(map[string][]*Service, len(n.services))
+			for path, backends := range n.services {
+				cp := make([]*Service, len(backends))
+				copy(cp, backends)
+				snapshot[path] = cp
+			}
+			n.mux.RUnlock()
+			for path, backends := range snapshot {
+				for _, s := range backends {
+					if s.GetStatus() == PAUSE {
+						continue
+					}
+					if s.recordProbe(probeService(s)) {
+						if err := n.UpdateStorage(path, s); err != nil {
 							log.Warn(err.Error())
 						}
+						n.publishEvent("status", path, s)
 					}
 				}
 			}
@@ -198,35 +702,35 @@ func (n *Novis) healthCheck() {
 	}
 }
 
-// UpdateStorage - update to redis store
-func (n *Novis) UpdateStorage() (err error) {
-	ctx := context.Background()
-	svcJSON, err := json.Marshal(n.services)
-	if err != nil {
-		return err
-	}
-	err = n.storage.Set(ctx, svcKey, string(svcJSON), 0).Err()
-	return err
-}
-
-// AddService - Add service to map
+// AddService - Add a backend for a path to the pool
 func (n *Novis) AddService(service *Service) (err error) {
-	p := strings.Trim(service.Path, "/")
-	if len(p) > 0 {
-		n.mux.Lock()
-		n.services[strings.ToLower(p)] = service
-		err = n.UpdateStorage()
-		n.mux.Unlock()
+	p := strings.ToLower(strings.Trim(service.Path, "/"))
+	if len(p) == 0 {
 		return nil
 	}
-	return nil
+	n.addServiceLocal(service)
+	err = n.UpdateStorage(p, service)
+	n.publishEvent("add", p, service)
+	return err
 }
 
 // PauseService - Pause a service
 func (n *Novis) PauseService(service *Service) {
 	p := strings.Split(service.Path, "/")
 	if len(p) > 0 {
-		n.services[strings.ToLower(p[0])].SetStatus(PAUSE)
+		lp := strings.ToLower(p[0])
+		n.mux.RLock()
+		backends := n.services[lp]
+		n.mux.RUnlock()
+		for _, b := range backends {
+			if b.GetHost() == service.Host {
+				b.SetStatus(PAUSE)
+				if err := n.UpdateStorage(lp, b); err != nil {
+					log.Warn(err.Error())
+				}
+				n.publishEvent("pause", lp, b)
+			}
+		}
 	}
 }
 
@@ -234,27 +738,59 @@ func (n *Novis) PauseService(service *Service) {
 func (n *Novis) ResumeService(service *Service) {
 	p := strings.Split(service.Path, "/")
 	if len(p) > 0 {
-		n.services[strings.ToLower(p[0])].SetStatus(CHECKING)
+		lp := strings.ToLower(p[0])
+		n.mux.RLock()
+		backends := n.services[lp]
+		n.mux.RUnlock()
+		for _, b := range backends {
+			if b.GetHost() == service.Host {
+				b.SetStatus(CHECKING)
+				if err := n.UpdateStorage(lp, b); err != nil {
+					log.Warn(err.Error())
+				}
+				n.publishEvent("resume", lp, b)
+			}
+		}
 	}
 }
 
-// RemoveService - Remove service from map
+// RemoveService - Remove a backend for a path from the pool
 func (n *Novis) RemoveService(service *Service) (err error) {
 	p := strings.Split(service.Path, "/")
 	if len(p) > 0 {
 		lp := strings.ToLower(p[0])
 		n.mux.Lock()
-		_, found := n.services[lp]
+		backends, found := n.services[lp]
 		if found {
-			delete(n.services, lp)
-			err = n.UpdateStorage()
+			remaining := make([]*Service, 0, len(backends))
+			for _, b := range backends {
+				if b.GetHost() != service.Host {
+					remaining = append(remaining, b)
+				}
+			}
+			if len(remaining) > 0 {
+				n.services[lp] = remaining
+			} else {
+				delete(n.services, lp)
+			}
 		}
 		n.mux.Unlock()
+		if found {
+			err = n.removeServiceFromStorage(lp, service)
+			n.publishEvent("remove", lp, service)
+		}
 		return err
 	}
 	return err
 }
 
+// discoveryHandler - Adapt discovery to an http.Handler for mounting on the mux
+func (n *Novis) discoveryHandler(res http.ResponseWriter, req *http.Request) {
+	if err := n.discovery(res, req); err != nil {
+		log.Warn(err.Error())
+	}
+}
+
 // discovery - Discovery service
 func (n *Novis) discovery(res http.ResponseWriter, req *http.Request) error {
 	paths := removeEmptyStr(strings.Split(req.URL.Path, "/"))
@@ -282,13 +818,15 @@ func (n *Novis) discovery(res http.ResponseWriter, req *http.Request) error {
 		Respond(res, http.StatusBadRequest, nil, nil)
 		return err
 	}
-	proxy := httputil.NewSingleHostReverseProxy(sURL)
-	s.SetReverseProxy(proxy)
+	path := strings.ToLower(strings.Trim(s.Path, "/"))
+	s.SetReverseProxy(n.newReverseProxy(path, sURL, &s))
 	n.AddService(&s)
 	return nil
 }
 
 func (n *Novis) findServiceInMap(path string) string {
+	n.mux.RLock()
+	defer n.mux.RUnlock()
 	for k := range n.services {
 		if strings.HasPrefix(strings.ToLower(path), strings.ToLower(k)) {
 			return k
@@ -297,43 +835,99 @@ func (n *Novis) findServiceInMap(path string) string {
 	return ""
 }
 
+// hashKeyFor - Derive the rendezvous hashing key for a request per ProxyOptions.HashKey
+func (n *Novis) hashKeyFor(req *http.Request) string {
+	switch {
+	case n.opts.HashKey == "clientIP":
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			return req.RemoteAddr
+		}
+		return host
+	case strings.HasPrefix(n.opts.HashKey, "header:"):
+		return req.Header.Get(strings.TrimPrefix(n.opts.HashKey, "header:"))
+	default:
+		return req.URL.Path
+	}
+}
+
+// pickBackend - Pick a backend for path using weighted rendezvous (HRW) hashing. A backend whose
+// circuit breaker is OPEN is only skipped while a non-OPEN UP peer is available for the same
+// path; if every UP backend is OPEN, the best-scoring one is still returned so proxyRequest's
+// breakerAllow can drive its cooldown->HALF_OPEN probe (and 503 otherwise) instead of the path
+// going fully unroutable.
+func (n *Novis) pickBackend(path string, key string) *Service {
+	n.mux.RLock()
+	backends := make([]*Service, len(n.services[path]))
+	copy(backends, n.services[path])
+	n.mux.RUnlock()
+	var best, bestOpen *Service
+	var bestScore, bestOpenScore float64
+	for _, s := range backends {
+		if s.GetStatus() != UP {
+			continue
+		}
+		score := rendezvousScore(s, key)
+		if s.breaker != nil && s.breaker.currentState() == breakerOpen {
+			if bestOpen == nil || score > bestOpenScore {
+				bestOpen = s
+				bestOpenScore = score
+			}
+			continue
+		}
+		if best == nil || score > bestScore {
+			best = s
+			bestScore = score
+		}
+	}
+	if best != nil {
+		return best
+	}
+	return bestOpen
+}
+
 // proxyRequest - Proxy request to service
 func (n *Novis) proxyRequest(res http.ResponseWriter, req *http.Request) {
 	paths := strings.Trim(req.URL.Path, "/")
 	if len(paths) > 0 {
 		sn := n.findServiceInMap(paths)
-		if paths == strings.ToLower(n.opts.DiscoveryURL) {
-			_ = n.discovery(res, req)
-		} else {
-			n.mux.RLock()
-			s, found := n.services[sn]
-			n.mux.RUnlock()
-			if found {
-				if s.GetStatus() != UP {
-					Respond(res, http.StatusNotFound, nil, nil)
-					return
-				}
-				sURL, err := url.Parse(s.GetHost())
-				if err != nil {
-					log.WithField("URL", s.GetHost()).Warn(err.Error())
-					Respond(res, http.StatusBadGateway, nil, nil)
-					return
+		s := n.pickBackend(sn, n.hashKeyFor(req))
+		if s != nil {
+			if ok, retryAfter := n.breakerAllow(sn, s); !ok {
+				secs := int(retryAfter.Seconds())
+				if secs < 1 {
+					secs = 1
 				}
-				req.URL.Path = strings.Replace(req.URL.Path, "/"+s.GetPath(), "", 1)
-				req.RequestURI = strings.Replace(req.RequestURI, "/"+s.GetPath(), "", 1)
-				req.Host = sURL.Host
-				s.GetReverseProxy().ServeHTTP(res, req)
+				res.Header().Set("Retry-After", strconv.Itoa(secs))
+				Respond(res, http.StatusServiceUnavailable, nil, nil)
 				return
 			}
-			Respond(res, http.StatusNotFound, nil, nil)
+			sURL, err := url.Parse(s.GetHost())
+			if err != nil {
+				log.WithField("URL", s.GetHost()).Warn(err.Error())
+				Respond(res, http.StatusBadGateway, nil, nil)
+				return
+			}
+			req.URL.Path = strings.Replace(req.URL.Path, "/"+s.GetPath(), "", 1)
+			req.RequestURI = strings.Replace(req.RequestURI, "/"+s.GetPath(), "", 1)
+			req.Host = sURL.Host
+			timeout := n.opts.Timeout
+			if timeout <= 0 {
+				timeout = 10 * time.Second
+			}
+			ctx, cancel := context.WithTimeout(req.Context(), timeout)
+			defer cancel()
+			s.GetReverseProxy().ServeHTTP(res, req.WithContext(ctx))
+			return
 		}
+		Respond(res, http.StatusNotFound, nil, nil)
 	} else {
 		Respond(res, http.StatusOK, nil, nil)
 	}
 }
 
-// GetAllServices - Get all available services
-func (n *Novis) GetAllServices() (ss map[string]*Service) {
+// GetAllServices - Get all available services, keyed by path
+func (n *Novis) GetAllServices() (ss map[string][]*Service) {
 	n.mux.RLock()
 	ss = n.services
 	n.mux.RUnlock()
@@ -387,6 +981,40 @@ func (s *Service) GetPath() (p string) {
 	return p
 }
 
+// setBreakerState - Mirror the circuit breaker's state onto the exported BreakerState field
+func (s *Service) setBreakerState(state breakerState) {
+	if s.mux != nil {
+		s.mux.Lock()
+		s.BreakerState = string(state)
+		s.mux.Unlock()
+	} else {
+		s.BreakerState = string(state)
+	}
+}
+
+// SetWeight - Set service weight
+func (s *Service) SetWeight(w int) {
+	if s.mux != nil {
+		s.mux.Lock()
+		s.Weight = w
+		s.mux.Unlock()
+	} else {
+		s.Weight = w
+	}
+}
+
+// GetWeight - Get service weight
+func (s *Service) GetWeight() (w int) {
+	if s.mux != nil {
+		s.mux.RLock()
+		w = s.Weight
+		s.mux.RUnlock()
+	} else {
+		w = s.Weight
+	}
+	return w
+}
+
 // SetReverseProxy - Set reverse proxy
 func (s *Service) SetReverseProxy(rp *httputil.ReverseProxy) {
 	if s.mux != nil {
@@ -433,25 +1061,98 @@ func (s *Service) GetHealthCheckURL() (hcu string) {
 	return hcu
 }
 
-// Close - Close down server
-func (n *Novis) Close() (err error) {
-	err = n.server.Close()
-	if err != nil {
-		return err
+// recordProbe - Apply a health probe result against the configured thresholds, only flipping
+// Status after enough consecutive results; returns whether Status changed
+func (s *Service) recordProbe(alive bool) (changed bool) {
+	unhealthyThreshold := s.HealthCheckUnhealthyThreshold
+	if unhealthyThreshold <= 0 {
+		unhealthyThreshold = 1
+	}
+	healthyThreshold := s.HealthyThreshold
+	if healthyThreshold <= 0 {
+		healthyThreshold = 1
+	}
+	if s.mux != nil {
+		s.mux.Lock()
+		defer s.mux.Unlock()
+	}
+	if alive {
+		s.failCount = 0
+		s.okCount++
+		if s.Status != UP && s.okCount >= healthyThreshold {
+			s.Status = UP
+			changed = true
+		}
+	} else {
+		s.okCount = 0
+		s.failCount++
+		if s.Status != DOWN && s.failCount >= unhealthyThreshold {
+			s.Status = DOWN
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Shutdown - Gracefully stop the proxy: stop accepting new connections, let in-flight requests
+// finish within ctx, stop the health-check and pub/sub goroutines, and close the Redis client
+func (n *Novis) Shutdown(ctx context.Context) (err error) {
+	if n.cancel != nil {
+		n.cancel()
+	}
+	err = n.server.Shutdown(ctx)
+	if cErr := n.closeEvents(); cErr != nil && err == nil {
+		err = cErr
+	}
+	if cErr := n.storage.Close(); cErr != nil && err == nil {
+		err = cErr
 	}
-	err = n.storage.Close()
 	return err
 }
 
-// isServiceAlive - Check if a service is available to connect
-func isServiceAlive(u string) ServiceStatus {
-	to := 3 * time.Second
-	conn, err := net.DialTimeout("tcp", u, to)
+// probeService - Probe a backend's health check URL, honoring its configured method, timeout,
+// expected status, and expected body pattern
+func probeService(s *Service) bool {
+	u := s.GetHealthCheckURL()
+	if len(u) == 0 {
+		return false
+	}
+	method := s.HealthCheckMethod
+	if len(method) == 0 {
+		method = http.MethodGet
+	}
+	timeout := s.HealthCheckTimeout
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	req, err := http.NewRequest(method, u, nil)
 	if err != nil {
-		return DOWN
+		return false
+	}
+	client := &http.Client{Timeout: timeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+	expectStatus := s.HealthCheckExpectStatus
+	if expectStatus == 0 {
+		expectStatus = http.StatusOK
+	}
+	if res.StatusCode != expectStatus {
+		return false
+	}
+	if len(s.HealthCheckExpectBody) > 0 {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return false
+		}
+		matched, err := regexp.MatchString(s.HealthCheckExpectBody, string(body))
+		if err != nil || !matched {
+			return false
+		}
 	}
-	_ = conn.Close()
-	return UP
+	return true
 }
 
 // Respond - Respond back to client
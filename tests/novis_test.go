@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -11,6 +12,6 @@ func TestNovisShouldStartUp(t *testing.T) {
 	server := novis.New(8080, nil)
 	_ = server.Start(func(n *novis.Novis) {
 		time.Sleep(3 * time.Second)
-		_ = n.Close()
+		_ = n.Shutdown(context.Background())
 	})
 }
@@ -1,6 +1,8 @@
 package novis
 
 import (
+	"hash/fnv"
+	"math"
 	"math/rand"
 	"strings"
 )
@@ -30,3 +32,23 @@ func removeEmptyStr(l []string) []string {
 func randomIntegerInRange(min int, max int) int {
 	return min + rand.Intn(max-min)
 }
+
+// rendezvousScore - Compute the weighted HRW score for a backend against a hash key
+func rendezvousScore(s *Service, key string) float64 {
+	w := s.GetWeight()
+	if w <= 0 {
+		w = 1
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s.GetHost() + "|" + key))
+	u := float64(h.Sum64()) / float64(math.MaxUint64)
+	if u <= 0 {
+		u = math.SmallestNonzeroFloat64
+	}
+	return float64(w) * (-1 / math.Log(u))
+}
+
+// hashField - Redis hash field under svcKey that identifies a single backend
+func hashField(path string, s *Service) string {
+	return path + "|" + strings.ToLower(s.Host)
+}